@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// collectCodeSamples looks for <dir>/<operationID>/<lang>.<ext> files and
+// returns one x-codeSamples entry per file found, sorted by language for
+// deterministic output.
+func collectCodeSamples(dir, operationID string) []map[string]string {
+	if dir == "" || operationID == "" {
+		return nil
+	}
+	opDir := filepath.Join(dir, operationID)
+	entries, err := os.ReadDir(opDir)
+	if err != nil {
+		return nil
+	}
+	var samples []map[string]string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		lang := strings.TrimSuffix(name, filepath.Ext(name))
+		if lang == "" {
+			continue
+		}
+		source, err := os.ReadFile(filepath.Join(opDir, name))
+		if err != nil {
+			continue
+		}
+		samples = append(samples, map[string]string{
+			"lang":   lang,
+			"source": string(source),
+			"label":  strings.ToUpper(lang[:1]) + lang[1:],
+		})
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i]["lang"] < samples[j]["lang"] })
+	return samples
+}
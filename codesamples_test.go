@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCollectCodeSamples(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name        string
+		operationID string
+		files       map[string]string
+		want        []map[string]string
+	}{
+		{
+			name:        "no directory for operation",
+			operationID: "GetMissing",
+			want:        nil,
+		},
+		{
+			name:        "sorted by language",
+			operationID: "GetWidget",
+			files: map[string]string{
+				"python.py": "print('hi')",
+				"go.go":     `fmt.Println("hi")`,
+			},
+			want: []map[string]string{
+				{"lang": "go", "source": `fmt.Println("hi")`, "label": "Go"},
+				{"lang": "python", "source": "print('hi')", "label": "Python"},
+			},
+		},
+		{
+			name:        "empty operation ID",
+			operationID: "",
+			want:        nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.files) > 0 {
+				opDir := filepath.Join(dir, tt.operationID)
+				if err := os.MkdirAll(opDir, 0o755); err != nil {
+					t.Fatalf("mkdir: %v", err)
+				}
+				for name, contents := range tt.files {
+					if err := os.WriteFile(filepath.Join(opDir, name), []byte(contents), 0o644); err != nil {
+						t.Fatalf("write %s: %v", name, err)
+					}
+				}
+			}
+
+			got := collectCodeSamples(dir, tt.operationID)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d samples, want %d: %v", len(got), len(tt.want), got)
+			}
+			for i, want := range tt.want {
+				for k, v := range want {
+					if got[i][k] != v {
+						t.Errorf("sample %d[%q] = %q, want %q", i, k, got[i][k], v)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+type specChange struct {
+	breaking bool
+	message  string
+}
+
+// diffAgainstPrevious compares the newly generated spec against a previously
+// generated swagger.json, classifying each change as breaking or
+// non-breaking, and writes the result to <destDir>/<apiDir>/diff.md.
+func diffAgainstPrevious(destDir, apiDir, previousFile string, newDoc map[string]interface{}) ([]specChange, error) {
+	prevRaw, err := os.ReadFile(previousFile)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	var oldDoc map[string]interface{}
+	if err = json.Unmarshal(prevRaw, &oldDoc); err != nil {
+		return nil, errs.Wrap(err)
+	}
+
+	var changes []specChange
+	changes = append(changes, diffPaths(mapAt(oldDoc, "paths"), mapAt(newDoc, "paths"))...)
+	changes = append(changes, diffDefinitions(mapAt(oldDoc, "definitions"), mapAt(newDoc, "definitions"))...)
+
+	if err = writeDiffReport(filepath.Join(destDir, apiDir, "diff.md"), changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func mapAt(doc map[string]interface{}, key string) map[string]interface{} {
+	m, _ := doc[key].(map[string]interface{})
+	return m
+}
+
+func isHTTPMethod(method string) bool {
+	switch method {
+	case "get", "post", "put", "delete", "patch", "head", "options":
+		return true
+	default:
+		return false
+	}
+}
+
+func diffPaths(oldPaths, newPaths map[string]interface{}) []specChange {
+	var changes []specChange
+	for path, oldV := range oldPaths {
+		oldPathItem, _ := oldV.(map[string]interface{})
+		newV, exists := newPaths[path]
+		if !exists {
+			changes = append(changes, specChange{true, fmt.Sprintf("removed path `%s`", path)})
+			continue
+		}
+		newPathItem, _ := newV.(map[string]interface{})
+		for method, oldOpV := range oldPathItem {
+			if !isHTTPMethod(method) {
+				continue
+			}
+			oldOp, _ := oldOpV.(map[string]interface{})
+			newOpV, ok := newPathItem[method]
+			if !ok {
+				changes = append(changes, specChange{true, fmt.Sprintf("removed operation `%s %s`", strings.ToUpper(method), path)})
+				continue
+			}
+			newOp, _ := newOpV.(map[string]interface{})
+			changes = append(changes, diffOperation(path, method, oldOp, newOp)...)
+		}
+	}
+	for path, newV := range newPaths {
+		oldV, exists := oldPaths[path]
+		if !exists {
+			changes = append(changes, specChange{false, fmt.Sprintf("added path `%s`", path)})
+			continue
+		}
+		newPathItem, _ := newV.(map[string]interface{})
+		oldPathItem, _ := oldV.(map[string]interface{})
+		for method := range newPathItem {
+			if !isHTTPMethod(method) {
+				continue
+			}
+			if _, ok := oldPathItem[method]; !ok {
+				changes = append(changes, specChange{false, fmt.Sprintf("added operation `%s %s`", strings.ToUpper(method), path)})
+			}
+		}
+	}
+	return changes
+}
+
+func diffOperation(path, method string, oldOp, newOp map[string]interface{}) []specChange {
+	var changes []specChange
+	label := fmt.Sprintf("`%s %s`", strings.ToUpper(method), path)
+
+	oldParams := paramsByKey(oldOp)
+	newParams := paramsByKey(newOp)
+	for key, oldParam := range oldParams {
+		newParam, ok := newParams[key]
+		required, _ := oldParam["required"].(bool)
+		if !ok {
+			changes = append(changes, specChange{required, fmt.Sprintf("%s: removed %s parameter `%s`", label, requiredLabel(required), paramName(oldParam))})
+			continue
+		}
+		if oldParam["type"] != newParam["type"] || oldParam["format"] != newParam["format"] {
+			changes = append(changes, specChange{true, fmt.Sprintf("%s: changed type/format of parameter `%s`", label, paramName(oldParam))})
+		}
+		if oldParam["in"] == "body" {
+			oldSchema, _ := oldParam["schema"].(map[string]interface{})
+			newSchema, _ := newParam["schema"].(map[string]interface{})
+			changes = append(changes, diffBodySchema(label, oldSchema, newSchema)...)
+		}
+	}
+	for key, newParam := range newParams {
+		if _, ok := oldParams[key]; ok {
+			continue
+		}
+		required, _ := newParam["required"].(bool)
+		changes = append(changes, specChange{required, fmt.Sprintf("%s: added %s parameter `%s`", label, requiredLabel(required), paramName(newParam))})
+	}
+
+	oldResponses := mapAt(oldOp, "responses")
+	newResponses := mapAt(newOp, "responses")
+	for code := range oldResponses {
+		if _, ok := newResponses[code]; !ok {
+			changes = append(changes, specChange{true, fmt.Sprintf("%s: removed response `%s`", label, code)})
+		}
+	}
+	for code := range newResponses {
+		if _, ok := oldResponses[code]; !ok {
+			changes = append(changes, specChange{false, fmt.Sprintf("%s: added response `%s`", label, code)})
+		}
+	}
+	return changes
+}
+
+// diffBodySchema walks an inline (non-$ref) request body schema's properties,
+// since Swagger 2.0 body parameters nest their schema directly on the
+// parameter rather than referencing a named top-level definition, so changes
+// there aren't caught by diffDefinitions/diffSchema.
+func diffBodySchema(label string, oldSchema, newSchema map[string]interface{}) []specChange {
+	var changes []specChange
+	oldProps := mapAt(oldSchema, "properties")
+	newProps := mapAt(newSchema, "properties")
+	oldRequired := stringSet(oldSchema["required"])
+	newRequired := stringSet(newSchema["required"])
+
+	for prop, oldPropSchema := range oldProps {
+		newPropSchema, ok := newProps[prop]
+		required := oldRequired[prop] || newRequired[prop]
+		if !ok {
+			changes = append(changes, specChange{true, fmt.Sprintf("%s: removed body property `%s`", label, prop)})
+			continue
+		}
+		oldPropMap, _ := oldPropSchema.(map[string]interface{})
+		newPropMap, _ := newPropSchema.(map[string]interface{})
+		oldType, newType := oldPropMap["type"], newPropMap["type"]
+		oldRef, newRef := oldPropMap["$ref"], newPropMap["$ref"]
+		if oldType != newType || oldRef != newRef {
+			changes = append(changes, specChange{required, fmt.Sprintf("%s: changed type of %s body property `%s`", label, requiredLabel(required), prop)})
+		}
+	}
+	for prop := range newProps {
+		if _, ok := oldProps[prop]; ok {
+			continue
+		}
+		required := newRequired[prop]
+		changes = append(changes, specChange{required, fmt.Sprintf("%s: added %s body property `%s`", label, requiredLabel(required), prop)})
+	}
+	return changes
+}
+
+func requiredLabel(required bool) string {
+	if required {
+		return "required"
+	}
+	return "optional"
+}
+
+func paramName(param map[string]interface{}) string {
+	name, _ := param["name"].(string)
+	return name
+}
+
+func paramsByKey(op map[string]interface{}) map[string]map[string]interface{} {
+	out := make(map[string]map[string]interface{})
+	params, _ := op["parameters"].([]interface{})
+	for _, pv := range params {
+		param, ok := pv.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := param["name"].(string)
+		in, _ := param["in"].(string)
+		out[in+":"+name] = param
+	}
+	return out
+}
+
+func diffDefinitions(oldDefs, newDefs map[string]interface{}) []specChange {
+	var changes []specChange
+	for name, oldV := range oldDefs {
+		oldSchema, _ := oldV.(map[string]interface{})
+		newV, ok := newDefs[name]
+		if !ok {
+			changes = append(changes, specChange{true, fmt.Sprintf("removed schema `%s`", name)})
+			continue
+		}
+		newSchema, _ := newV.(map[string]interface{})
+		changes = append(changes, diffSchema(name, oldSchema, newSchema)...)
+	}
+	for name := range newDefs {
+		if _, ok := oldDefs[name]; !ok {
+			changes = append(changes, specChange{false, fmt.Sprintf("added schema `%s`", name)})
+		}
+	}
+	return changes
+}
+
+func diffSchema(name string, oldSchema, newSchema map[string]interface{}) []specChange {
+	var changes []specChange
+	oldProps := mapAt(oldSchema, "properties")
+	newProps := mapAt(newSchema, "properties")
+	oldRequired := stringSet(oldSchema["required"])
+	newRequired := stringSet(newSchema["required"])
+
+	for prop, oldPropSchema := range oldProps {
+		newPropSchema, ok := newProps[prop]
+		required := oldRequired[prop] || newRequired[prop]
+		if !ok {
+			changes = append(changes, specChange{true, fmt.Sprintf("schema `%s`: removed property `%s`", name, prop)})
+			continue
+		}
+		oldPropMap, _ := oldPropSchema.(map[string]interface{})
+		newPropMap, _ := newPropSchema.(map[string]interface{})
+		oldType, newType := oldPropMap["type"], newPropMap["type"]
+		oldRef, newRef := oldPropMap["$ref"], newPropMap["$ref"]
+		if oldType != newType || oldRef != newRef {
+			changes = append(changes, specChange{required, fmt.Sprintf("schema `%s`: changed type of %s property `%s`", name, requiredLabel(required), prop)})
+		}
+	}
+	for prop := range newProps {
+		if _, ok := oldProps[prop]; ok {
+			continue
+		}
+		required := newRequired[prop]
+		changes = append(changes, specChange{required, fmt.Sprintf("schema `%s`: added %s property `%s`", name, requiredLabel(required), prop)})
+	}
+	return changes
+}
+
+func stringSet(v interface{}) map[string]bool {
+	out := make(map[string]bool)
+	arr, _ := v.([]interface{})
+	for _, s := range arr {
+		if str, ok := s.(string); ok {
+			out[str] = true
+		}
+	}
+	return out
+}
+
+func writeDiffReport(path string, changes []specChange) error {
+	var breaking, nonBreaking []specChange
+	for _, c := range changes {
+		if c.breaking {
+			breaking = append(breaking, c)
+		} else {
+			nonBreaking = append(nonBreaking, c)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Spec Diff Report\n\n")
+	sb.WriteString(fmt.Sprintf("## Breaking changes (%d)\n\n", len(breaking)))
+	if len(breaking) == 0 {
+		sb.WriteString("None.\n\n")
+	} else {
+		for _, c := range breaking {
+			sb.WriteString("- " + c.message + "\n")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(fmt.Sprintf("## Non-breaking changes (%d)\n\n", len(nonBreaking)))
+	if len(nonBreaking) == 0 {
+		sb.WriteString("None.\n")
+	} else {
+		for _, c := range nonBreaking {
+			sb.WriteString("- " + c.message + "\n")
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(sb.String()), 0o644); err != nil {
+		return errs.Wrap(err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDiffOperation(t *testing.T) {
+	tests := []struct {
+		name        string
+		oldOp       string
+		newOp       string
+		wantCount   int
+		wantBreak   int
+		wantMessage string
+	}{
+		{
+			name:      "no changes",
+			oldOp:     `{"parameters": [], "responses": {"200": {}}}`,
+			newOp:     `{"parameters": [], "responses": {"200": {}}}`,
+			wantCount: 0,
+		},
+		{
+			name:        "parameter type changed is breaking",
+			oldOp:       `{"parameters": [{"name": "id", "in": "query", "type": "string"}]}`,
+			newOp:       `{"parameters": [{"name": "id", "in": "query", "type": "integer"}]}`,
+			wantCount:   1,
+			wantBreak:   1,
+			wantMessage: "changed type/format of parameter `id`",
+		},
+		{
+			name:        "required parameter added",
+			oldOp:       `{"parameters": []}`,
+			newOp:       `{"parameters": [{"name": "id", "in": "query", "type": "string", "required": true}]}`,
+			wantCount:   1,
+			wantBreak:   1,
+			wantMessage: "added required parameter `id`",
+		},
+		{
+			name:        "optional parameter added is non-breaking",
+			oldOp:       `{"parameters": []}`,
+			newOp:       `{"parameters": [{"name": "id", "in": "query", "type": "string"}]}`,
+			wantCount:   1,
+			wantBreak:   0,
+			wantMessage: "added optional parameter `id`",
+		},
+		{
+			name:        "response removed is breaking",
+			oldOp:       `{"responses": {"200": {}, "404": {}}}`,
+			newOp:       `{"responses": {"200": {}}}`,
+			wantCount:   1,
+			wantBreak:   1,
+			wantMessage: "removed response `404`",
+		},
+		{
+			name: "added required inline body property is breaking",
+			oldOp: `{"parameters": [{"name": "body", "in": "body", "schema": {
+				"type": "object",
+				"properties": {"name": {"type": "string"}}
+			}}]}`,
+			newOp: `{"parameters": [{"name": "body", "in": "body", "schema": {
+				"type": "object",
+				"properties": {"name": {"type": "string"}, "email": {"type": "string"}},
+				"required": ["email"]
+			}}]}`,
+			wantCount:   1,
+			wantBreak:   1,
+			wantMessage: "added required body property `email`",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var oldOp, newOp map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.oldOp), &oldOp); err != nil {
+				t.Fatalf("unmarshal oldOp: %v", err)
+			}
+			if err := json.Unmarshal([]byte(tt.newOp), &newOp); err != nil {
+				t.Fatalf("unmarshal newOp: %v", err)
+			}
+
+			changes := diffOperation("/widgets", "get", oldOp, newOp)
+			if len(changes) != tt.wantCount {
+				t.Fatalf("got %d changes, want %d: %+v", len(changes), tt.wantCount, changes)
+			}
+			if tt.wantCount == 0 {
+				return
+			}
+			breaking := 0
+			var found bool
+			for _, c := range changes {
+				if c.breaking {
+					breaking++
+				}
+				if tt.wantMessage != "" && strings.Contains(c.message, tt.wantMessage) {
+					found = true
+				}
+			}
+			if breaking != tt.wantBreak {
+				t.Errorf("got %d breaking changes, want %d: %+v", breaking, tt.wantBreak, changes)
+			}
+			if tt.wantMessage != "" && !found {
+				t.Errorf("expected a change containing %q, got %+v", tt.wantMessage, changes)
+			}
+		})
+	}
+}
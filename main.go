@@ -36,8 +36,23 @@ func main() {
 	firstTagOnly := false
 	generateHtml := true
 	badges := ""
+	specVersion := "2.0"
+	validateSpec := false
+	splitByTag := false
+	renderer := "rapidoc"
+	rendererAssets := ""
+	codeSamplesDir := ""
+	defaultSecurity := ""
+	diffAgainst := ""
+	failOnBreaking := false
+	watch := false
+	serveAddr := ""
 
 	var exclude []string
+	var secAPIKeys []string
+	var secBearers []string
+	var secBasics []string
+	var secOAuth2s []string
 	cl.NewGeneralOption(&searchDir).SetSingle('s').SetName("search").SetArg("dir").SetUsage("The directory root to search for documentation directives")
 	cl.NewGeneralOption(&mainAPIFile).SetSingle('m').SetName("main").SetArg("file").SetUsage("The Go file to search for the main documentation directives")
 	cl.NewGeneralOption(&destDir).SetSingle('o').SetName("output").SetArg("dir").SetUsage("The destination directory to write the documentation files to")
@@ -54,32 +69,93 @@ func main() {
 	cl.NewGeneralOption(&firstTagOnly).SetSingle('f').SetName("firstTagOnly").SetUsage("Keep only the first tag in the list of tags for each API. This is useful for generating a single-page API documentation.")
 	cl.NewGeneralOption(&generateHtml).SetSingle('l').SetName("generateHtml").SetUsage("When set, embeds the spec directly in the html")
 	cl.NewGeneralOption(&badges).SetSingle('b').SetName("badges").SetArg("tag:color,...").SetUsage("Comma-separated list of tag:color pairs to generate badges")
+	cl.NewGeneralOption(&specVersion).SetName("spec-version").SetArg("version").SetUsage("The OpenAPI spec version to emit: 2.0, 3.0, or 3.1")
+	cl.NewGeneralOption(&validateSpec).SetName("validate").SetUsage("Validate the generated spec and fail the build with diagnostics if it is invalid")
+	cl.NewGeneralOption(&splitByTag).SetName("split-by-tag").SetUsage("Emit a separate documentation site per tag, plus a top-level index linking to each")
+	cl.NewGeneralOption(&renderer).SetName("renderer").SetArg("name").SetUsage("The HTML renderer to embed: rapidoc, swagger-ui, redoc, or elements")
+	cl.NewGeneralOption(&rendererAssets).SetName("renderer-assets").SetArg("local").SetUsage("When set to 'local', vendor the renderer's JS/CSS into <destDir>/<apiDir>/assets so the docs work offline")
+	cl.NewGeneralOption(&codeSamplesDir).SetName("code-samples").SetArg("dir").SetUsage("A directory of <operationID>/<lang>.<ext> files to inject as x-codeSamples")
+	cl.NewGeneralOption(&secAPIKeys).SetName("sec-apikey").SetArg("name:in:header-name[:description]").SetUsage("Add an apiKey security definition. Repeatable.")
+	cl.NewGeneralOption(&secBearers).SetName("sec-bearer").SetArg("name[:description]").SetUsage("Add a bearer token security definition. Repeatable.")
+	cl.NewGeneralOption(&secBasics).SetName("sec-basic").SetArg("name[:description]").SetUsage("Add a basic auth security definition. Repeatable.")
+	cl.NewGeneralOption(&secOAuth2s).SetName("sec-oauth2").SetArg("name:flow:authURL:tokenURL:scope=desc,...").SetUsage("Add an oauth2 security definition. Repeatable.")
+	cl.NewGeneralOption(&defaultSecurity).SetName("default-security").SetArg("name:scope1,scope2").SetUsage("Apply a global security requirement referencing a defined security scheme")
+	cl.NewGeneralOption(&diffAgainst).SetName("diff-against").SetArg("file").SetUsage("A previously generated swagger.json to diff against, writing <destDir>/<apiDir>/diff.md")
+	cl.NewGeneralOption(&failOnBreaking).SetName("fail-on-breaking").SetUsage("Exit non-zero when --diff-against finds breaking changes")
+	cl.NewGeneralOption(&watch).SetName("watch").SetUsage("Keep running, rebuilding whenever a watched file changes. Each rebuild still re-parses the whole tree via swag.ParseAPI; this only skips rebuilds for events where the file's bytes didn't actually change")
+	cl.NewGeneralOption(&serveAddr).SetName("serve").SetArg(":port").SetUsage("Serve the generated site and live-reload the browser via SSE on rebuild")
 
 	cl.Parse(os.Args[1:])
+	switch specVersion {
+	case "2.0", "3.0", "3.1":
+	default:
+		fmt.Printf("invalid --spec-version %q; must be one of 2.0, 3.0, 3.1\n", specVersion)
+		os.Exit(1)
+	}
+	if _, ok := renderers[renderer]; !ok {
+		fmt.Printf("invalid --renderer %q; must be one of rapidoc, swagger-ui, redoc, elements\n", renderer)
+		os.Exit(1)
+	}
+	rendererAssetsLocal := rendererAssets == "local"
 	if title == "" {
 		title = baseName
 	}
-	if err := generate(
-		searchDir,
-		mainAPIFile,
-		destDir,
-		apiDir,
-		baseName,
-		title,
-		serverURL,
-		tags,
-		markdownFileDir,
-		exclude,
-		maxDependencyDepth,
-		embedded,
-		useOldMethod,
-		firstTagOnly,
-		generateHtml,
-		badges,
-	); err != nil {
+	siteDir := filepath.Join(destDir, apiDir)
+	build := func() error {
+		if err := generate(
+			searchDir,
+			mainAPIFile,
+			destDir,
+			apiDir,
+			baseName,
+			title,
+			serverURL,
+			tags,
+			markdownFileDir,
+			exclude,
+			maxDependencyDepth,
+			embedded,
+			useOldMethod,
+			firstTagOnly,
+			generateHtml,
+			badges,
+			specVersion,
+			renderer,
+			codeSamplesDir,
+			validateSpec,
+			splitByTag,
+			rendererAssetsLocal,
+			secAPIKeys,
+			secBearers,
+			secBasics,
+			secOAuth2s,
+			defaultSecurity,
+			diffAgainst,
+			failOnBreaking,
+		); err != nil {
+			return err
+		}
+		if serveAddr != "" {
+			return injectLiveReloadScript(siteDir)
+		}
+		return nil
+	}
+
+	if err := build(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
+
+	switch {
+	case watch:
+		cache := newFileCache()
+		if err := runWatch(build, searchDir, markdownFileDir, cache, serveAddr, siteDir); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case serveAddr != "":
+		serveSite(serveAddr, siteDir, newReloadHub())
+	}
 }
 
 func generate(searchDir,
@@ -97,7 +173,20 @@ func generate(searchDir,
 	useOldMethod,
 	firstTagOnly,
 	generateHtml bool,
-	badges string,
+	badges,
+	specVersion,
+	renderer,
+	codeSamplesDir string,
+	validateSpec,
+	splitByTag,
+	rendererAssetsLocal bool,
+	secAPIKeys,
+	secBearers,
+	secBasics,
+	secOAuth2s []string,
+	defaultSecurity,
+	diffAgainst string,
+	failOnBreaking bool,
 ) error {
 
 	if err := os.MkdirAll(filepath.Join(destDir, apiDir), 0o755); err != nil {
@@ -126,6 +215,9 @@ func generate(searchDir,
 		return errs.Wrap(err)
 	}
 	swagger := parser.GetSwagger()
+	if err := applyCLISecurityDefinitions(swagger, secAPIKeys, secBearers, secBasics, secOAuth2s, defaultSecurity); err != nil {
+		return err
+	}
 
 	badgeMap := make(map[string]string)
 	if badges != "" {
@@ -148,31 +240,40 @@ func generate(searchDir,
 
 		for i, operation := range operations {
 
-			if operation == nil || len(operation.Tags) == 0 {
+			if operation == nil {
 				continue
 			}
 
-			log.Printf("Processing %s [%s]: Tags: %v\n", path, operation.Tags)
+			log.Printf("Processing %s [%s]: Tags: %v\n", path, operation.ID, operation.Tags)
 
-			var badgeList []map[string]string
-			for _, tag := range operation.Tags {
-				if color, ok := badgeMap[tag]; ok {
-					badgeList = append(badgeList, map[string]string{
-						"label": tag,
-						"color": color,
-					})
-				}
-			}
-			if len(badgeList) > 0 {
+			if samples := collectCodeSamples(codeSamplesDir, operation.ID); len(samples) > 0 {
 				if operation.VendorExtensible.Extensions == nil {
 					operation.VendorExtensible.Extensions = make(spec.Extensions)
 				}
-				operation.VendorExtensible.Extensions["x-badges"] = badgeList
+				operation.VendorExtensible.Extensions["x-codeSamples"] = samples
 			}
 
-			// Limit tags to first if --firstTagOnly is set
-			if firstTagOnly && len(operation.Tags) > 0 {
-				operation.Tags = []string{operation.Tags[0]}
+			if len(operation.Tags) > 0 {
+				var badgeList []map[string]string
+				for _, tag := range operation.Tags {
+					if color, ok := badgeMap[tag]; ok {
+						badgeList = append(badgeList, map[string]string{
+							"label": tag,
+							"color": color,
+						})
+					}
+				}
+				if len(badgeList) > 0 {
+					if operation.VendorExtensible.Extensions == nil {
+						operation.VendorExtensible.Extensions = make(spec.Extensions)
+					}
+					operation.VendorExtensible.Extensions["x-badges"] = badgeList
+				}
+
+				// Limit tags to first if --firstTagOnly is set
+				if firstTagOnly {
+					operation.Tags = []string{operation.Tags[0]}
+				}
 			}
 
 			opRefs[i] = operation
@@ -185,59 +286,82 @@ func generate(searchDir,
 		swagger.Paths.Paths[path] = pathItem
 	}
 
-	jData, err := json.MarshalIndent(swagger, "", "  ")
-	if err != nil {
-		return errs.Wrap(err)
+	var jData []byte
+	var err error
+	if specVersion == "2.0" {
+		if jData, err = json.MarshalIndent(swagger, "", "  "); err != nil {
+			return errs.Wrap(err)
+		}
+		if validateSpec {
+			if err = validateSwagger2(jData); err != nil {
+				return err
+			}
+		}
+	} else {
+		var doc map[string]interface{}
+		if doc, err = convertToOpenAPI3(swagger, specVersion); err != nil {
+			return err
+		}
+		if validateSpec {
+			if err = validateOpenAPI3(doc); err != nil {
+				return err
+			}
+		}
+		if jData, err = json.MarshalIndent(doc, "", "  "); err != nil {
+			return errs.Wrap(err)
+		}
 	}
-	if err = os.WriteFile(filepath.Join(destDir, apiDir, baseName+".json"), jData, 0o644); err != nil {
-		return errs.Wrap(err)
+	if err = writeDocSite(filepath.Join(destDir, apiDir), baseName, title, serverURL, renderer, embedded, generateHtml, rendererAssetsLocal, jData); err != nil {
+		return err
 	}
-	var specURL, extra, js string
-	if serverURL != "" {
-		extra = fmt.Sprintf(`
-          server-url="%s"`, serverURL)
-	}
-	if embedded {
-		js = fmt.Sprintf(`
-<script>
-    window.addEventListener("DOMContentLoaded", (event) => {
-        const rapidocEl = document.getElementById("rapidoc");
-        rapidocEl.loadSpec(%s)
-    })
-</script>`, string(jData))
-	} else {
-		specURL = fmt.Sprintf(`
-          spec-url="%s.json"`, baseName)
-	}
-	if generateHtml {
-		if err = os.WriteFile(filepath.Join(destDir, apiDir, "index.html"), []byte(fmt.Sprintf(`<!doctype html>
-<html>
-<head>
-    <meta charset="utf-8">
-	<title>%s</title>
-	<script src="https://cdnjs.cloudflare.com/ajax/libs/rapidoc/9.3.8/rapidoc-min.js"
-			integrity="sha512-0ES6eX4K9J1PrIEjIizv79dTlN5HwI2GW9Ku6ymb8dijMHF5CIplkS8N0iFJ/wl3GybCSqBJu8HDhiFkZRAf0g=="
-			crossorigin="anonymous"
-			referrerpolicy="no-referrer">
-	</script>
-</head>
-<body>
-<rapi-doc id="rapidoc"
-          theme="dark"
-          render-style="read"
-          schema-style="table"
-          schema-description-expanded="true"%s
-          allow-spec-file-download="true"%s
->
-</rapi-doc>%s
-</body>
-</html>`, title, specURL, extra, js)), 0o644); err != nil {
+
+	if splitByTag {
+		if err = splitSitesByTag(swagger, destDir, apiDir, baseName, title, serverURL, renderer, embedded, generateHtml, rendererAssetsLocal, badgeMap); err != nil {
+			return err
+		}
+	}
+
+	if diffAgainst != "" {
+		if specVersion != "2.0" {
+			log.Printf("warning: --diff-against always compares the Swagger 2.0 document shape (definitions, body parameters); "+
+				"with --spec-version %s it may misreport or miss changes if %s was generated in OpenAPI 3.x shape (components.schemas, requestBody)\n",
+				specVersion, diffAgainst)
+		}
+		rawSwagger, err := json.Marshal(swagger)
+		if err != nil {
 			return errs.Wrap(err)
 		}
+		var newDoc map[string]interface{}
+		if err = json.Unmarshal(rawSwagger, &newDoc); err != nil {
+			return errs.Wrap(err)
+		}
+		changes, err := diffAgainstPrevious(destDir, apiDir, diffAgainst, newDoc)
+		if err != nil {
+			return err
+		}
+		if failOnBreaking {
+			for _, c := range changes {
+				if c.breaking {
+					return errs.New("breaking changes detected against --diff-against spec; see diff.md")
+				}
+			}
+		}
 	}
 	return nil
 }
 
+// writeDocSite writes the spec JSON and the selected renderer's index.html
+// for a single documentation site rooted at dir.
+func writeDocSite(dir, baseName, title, serverURL, renderer string, embedded, generateHtml, rendererAssetsLocal bool, jData []byte) error {
+	if err := os.WriteFile(filepath.Join(dir, baseName+".json"), jData, 0o644); err != nil {
+		return errs.Wrap(err)
+	}
+	if !generateHtml {
+		return nil
+	}
+	return renderSite(renderer, dir, title, serverURL, embedded, jData, rendererAssetsLocal, baseName+".json")
+}
+
 type filter struct {
 	out *log.Logger
 }
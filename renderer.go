@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+// rendererAsset describes a single vendorable JS/CSS file a renderer template
+// loads from a CDN, along with the subresource-integrity hash used to verify
+// the CDN copy when it is vendored. The generated <script>/<link> tags carry
+// no integrity attribute.
+type rendererAsset struct {
+	url       string
+	integrity string
+	localName string
+}
+
+// rendererDef pairs a renderer's assets with the text/template used to build
+// its index.html. Templates reference assets via {{index .AssetPaths "name"}},
+// keyed by the asset's localName, which resolves to either the CDN URL or a
+// local "assets/<name>" path depending on --renderer-assets.
+type rendererDef struct {
+	assets []rendererAsset
+	tmpl   *template.Template
+}
+
+var renderers = map[string]rendererDef{
+	"rapidoc": {
+		assets: []rendererAsset{{
+			url:       "https://cdnjs.cloudflare.com/ajax/libs/rapidoc/9.3.8/rapidoc-min.js",
+			integrity: "sha512-0ES6eX4K9J1PrIEjIizv79dTlN5HwI2GW9Ku6ymb8dijMHF5CIplkS8N0iFJ/wl3GybCSqBJu8HDhiFkZRAf0g==",
+			localName: "rapidoc-min.js",
+		}},
+		tmpl: template.Must(template.New("rapidoc").Parse(rapidocTemplate)),
+	},
+	"swagger-ui": {
+		assets: []rendererAsset{
+			{
+				url:       "https://unpkg.com/swagger-ui-dist@5.17.14/swagger-ui.css",
+				integrity: "sha384-xtXEzsbCTJVhm/crjQOibSn7xeavz4I6g9wS9yeLv/vK96LKQY5K3gIM5Gv2TrLG",
+				localName: "swagger-ui.css",
+			},
+			{
+				url:       "https://unpkg.com/swagger-ui-dist@5.17.14/swagger-ui-bundle.js",
+				integrity: "sha384-JPAGHj5gH5pycZfHdfD/5vAD2x41FfK5qOcTrwIPE3qADJGXjAWdzTMprmecwOMD",
+				localName: "swagger-ui-bundle.js",
+			},
+		},
+		tmpl: template.Must(template.New("swagger-ui").Parse(swaggerUITemplate)),
+	},
+	"redoc": {
+		assets: []rendererAsset{{
+			url:       "https://cdn.jsdelivr.net/npm/redoc@2.1.3/bundles/redoc.standalone.js",
+			integrity: "sha384-RGZsqchGVdxqJ0M18XqGEWGJilgJ5o5xKJ9jd1EaMoPN2KXEQvjnEq8MXiqUIvwW",
+			localName: "redoc.standalone.js",
+		}},
+		tmpl: template.Must(template.New("redoc").Parse(redocTemplate)),
+	},
+	"elements": {
+		assets: []rendererAsset{
+			{
+				url:       "https://unpkg.com/@stoplight/elements@8.3.0/styles.min.css",
+				integrity: "sha384-ZZWeRmEq4SF6y0OjvT2Fo1CXRqCPJ6mtMLRy5AMzHqiGzUmp1zXz4qX5ngAB3d4u",
+				localName: "elements-styles.min.css",
+			},
+			{
+				url:       "https://unpkg.com/@stoplight/elements@8.3.0/web-components.min.js",
+				integrity: "sha384-PTZSdNGtFoQjklqfE0LuZ9K/yq3sk1u1fD0WlnZ5M9/LHJQzogT6LmTtuG2SqTUB",
+				localName: "elements-web-components.min.js",
+			},
+		},
+		tmpl: template.Must(template.New("elements").Parse(elementsTemplate)),
+	},
+}
+
+type rendererData struct {
+	Title      string
+	SpecURL    string
+	SpecJSON   string
+	ServerURL  string
+	Embedded   bool
+	AssetPaths map[string]string
+}
+
+// renderSite writes a renderer's index.html into dir, vendoring its CDN
+// assets into dir/assets when assetsLocal is true so the generated docs work
+// offline.
+func renderSite(renderer, dir, title, serverURL string, embedded bool, jData []byte, assetsLocal bool, specFileName string) error {
+	def, ok := renderers[renderer]
+	if !ok {
+		return errs.Newf("unknown --renderer %q", renderer)
+	}
+
+	assetPaths := make(map[string]string, len(def.assets))
+	for _, asset := range def.assets {
+		if assetsLocal {
+			if err := vendorAsset(dir, asset); err != nil {
+				return err
+			}
+			assetPaths[asset.localName] = "assets/" + asset.localName
+		} else {
+			assetPaths[asset.localName] = asset.url
+		}
+	}
+
+	data := rendererData{
+		Title:      title,
+		ServerURL:  serverURL,
+		Embedded:   embedded,
+		AssetPaths: assetPaths,
+	}
+	if embedded {
+		data.SpecJSON = string(jData)
+	} else {
+		data.SpecURL = specFileName
+	}
+
+	var buf bytes.Buffer
+	if err := def.tmpl.Execute(&buf, data); err != nil {
+		return errs.Wrap(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), buf.Bytes(), 0o644); err != nil {
+		return errs.Wrap(err)
+	}
+	return nil
+}
+
+// vendorAsset downloads a renderer asset into dir/assets, verifying its
+// SHA-384 or SHA-512 subresource-integrity hash before writing it to disk.
+func vendorAsset(dir string, asset rendererAsset) error {
+	assetsDir := filepath.Join(dir, "assets")
+	if err := os.MkdirAll(assetsDir, 0o755); err != nil {
+		return errs.Wrap(err)
+	}
+	dest := filepath.Join(assetsDir, asset.localName)
+	if _, err := os.Stat(dest); err == nil {
+		return nil
+	}
+	resp, err := http.Get(asset.url)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errs.Newf("failed to fetch %s: status %d", asset.url, resp.StatusCode)
+	}
+	var want string
+	if strings.HasPrefix(asset.integrity, "sha384-") {
+		sum := sha512.Sum384(body)
+		want = "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+	} else {
+		sum := sha512.Sum512(body)
+		want = "sha512-" + base64.StdEncoding.EncodeToString(sum[:])
+	}
+	if want != asset.integrity {
+		return errs.Newf("integrity check failed for %s: got %s, want %s", asset.url, want, asset.integrity)
+	}
+	if err = os.WriteFile(dest, body, 0o644); err != nil {
+		return errs.Wrap(err)
+	}
+	return nil
+}
+
+const rapidocTemplate = `<!doctype html>
+<html>
+<head>
+    <meta charset="utf-8">
+	<title>{{.Title}}</title>
+	<script src="{{index .AssetPaths "rapidoc-min.js"}}"
+			crossorigin="anonymous"
+			referrerpolicy="no-referrer">
+	</script>
+</head>
+<body>
+<rapi-doc id="rapidoc"
+          theme="dark"
+          render-style="read"
+          schema-style="table"
+          schema-description-expanded="true"
+          {{if .ServerURL}}server-url="{{.ServerURL}}"{{end}}
+          allow-spec-file-download="true"
+          {{if not .Embedded}}spec-url="{{.SpecURL}}"{{end}}
+>
+</rapi-doc>
+{{if .Embedded}}
+<script>
+    window.addEventListener("DOMContentLoaded", (event) => {
+        document.getElementById("rapidoc").loadSpec({{.SpecJSON}})
+    })
+</script>
+{{end}}
+</body>
+</html>`
+
+const swaggerUITemplate = `<!doctype html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <title>{{.Title}}</title>
+    <link rel="stylesheet" href="{{index .AssetPaths "swagger-ui.css"}}">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="{{index .AssetPaths "swagger-ui-bundle.js"}}"></script>
+<script>
+    window.onload = () => {
+        SwaggerUIBundle({
+            {{if .Embedded}}spec: {{.SpecJSON}},{{else}}url: "{{.SpecURL}}",{{end}}
+            dom_id: "#swagger-ui",
+        })
+    }
+</script>
+</body>
+</html>`
+
+const redocTemplate = `<!doctype html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <title>{{.Title}}</title>
+</head>
+<body>
+{{if .Embedded}}
+<div id="redoc-container"></div>
+{{else}}
+<redoc spec-url="{{.SpecURL}}"></redoc>
+{{end}}
+<script src="{{index .AssetPaths "redoc.standalone.js"}}"></script>
+{{if .Embedded}}
+<script>
+    Redoc.init({{.SpecJSON}}, {}, document.getElementById("redoc-container"))
+</script>
+{{end}}
+</body>
+</html>`
+
+const elementsTemplate = `<!doctype html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <title>{{.Title}}</title>
+    <link rel="stylesheet" href="{{index .AssetPaths "elements-styles.min.css"}}">
+</head>
+<body style="height: 100vh;">
+<elements-api
+    id="elements"
+    {{if not .Embedded}}apiDescriptionUrl="{{.SpecURL}}"{{end}}
+    router="hash"
+    layout="sidebar"
+>
+</elements-api>
+<script src="{{index .AssetPaths "elements-web-components.min.js"}}"></script>
+{{if .Embedded}}
+<script>
+    document.getElementById("elements").apiDescriptionDocument = {{.SpecJSON}}
+</script>
+{{end}}
+</body>
+</html>`
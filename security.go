@@ -0,0 +1,167 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/go-openapi/spec"
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+// applyCLISecurityDefinitions populates swagger.SecurityDefinitions and the
+// global swagger.Security requirement from CLI flags, so teams assembling a
+// spec from multiple services can add/override security schemes without
+// touching source comments.
+func applyCLISecurityDefinitions(swagger *spec.Swagger, apiKeys, bearers, basics, oauth2s []string, defaultSecurity string) error {
+	if len(apiKeys)+len(bearers)+len(basics)+len(oauth2s) == 0 && defaultSecurity == "" {
+		return nil
+	}
+	if swagger.SecurityDefinitions == nil {
+		swagger.SecurityDefinitions = make(spec.SecurityDefinitions)
+	}
+
+	for _, v := range apiKeys {
+		name, scheme, err := parseSecAPIKey(v)
+		if err != nil {
+			return err
+		}
+		swagger.SecurityDefinitions[name] = scheme
+	}
+	for _, v := range bearers {
+		name, scheme, err := parseSecBearer(v)
+		if err != nil {
+			return err
+		}
+		swagger.SecurityDefinitions[name] = scheme
+	}
+	for _, v := range basics {
+		name, scheme, err := parseSecBasic(v)
+		if err != nil {
+			return err
+		}
+		swagger.SecurityDefinitions[name] = scheme
+	}
+	for _, v := range oauth2s {
+		name, scheme, err := parseSecOAuth2(v)
+		if err != nil {
+			return err
+		}
+		swagger.SecurityDefinitions[name] = scheme
+	}
+
+	if defaultSecurity != "" {
+		name, scopes := parseDefaultSecurity(defaultSecurity)
+		swagger.Security = append(swagger.Security, map[string][]string{name: scopes})
+	}
+	return nil
+}
+
+func parseSecAPIKey(value string) (string, *spec.SecurityScheme, error) {
+	parts := strings.SplitN(value, ":", 4)
+	if len(parts) < 3 {
+		return "", nil, errs.Newf("invalid --sec-apikey %q; expected name:in:header-name[:description]", value)
+	}
+	scheme := spec.APIKeyAuth(parts[2], parts[1])
+	if len(parts) == 4 {
+		scheme.Description = parts[3]
+	}
+	return parts[0], scheme, nil
+}
+
+func parseSecBearer(value string) (string, *spec.SecurityScheme, error) {
+	parts := strings.SplitN(value, ":", 2)
+	if parts[0] == "" {
+		return "", nil, errs.Newf("invalid --sec-bearer %q; expected name[:description]", value)
+	}
+	scheme := spec.APIKeyAuth("Authorization", "header")
+	scheme.Description = `Bearer token, sent as "Authorization: Bearer <token>"`
+	if len(parts) == 2 {
+		scheme.Description = parts[1]
+	}
+	return parts[0], scheme, nil
+}
+
+func parseSecBasic(value string) (string, *spec.SecurityScheme, error) {
+	parts := strings.SplitN(value, ":", 2)
+	if parts[0] == "" {
+		return "", nil, errs.Newf("invalid --sec-basic %q; expected name[:description]", value)
+	}
+	scheme := spec.BasicAuth()
+	if len(parts) == 2 {
+		scheme.Description = parts[1]
+	}
+	return parts[0], scheme, nil
+}
+
+func parseSecOAuth2(value string) (string, *spec.SecurityScheme, error) {
+	parts := splitPreservingURLs(value)
+	if len(parts) < 4 {
+		return "", nil, errs.Newf("invalid --sec-oauth2 %q; expected name:flow:authURL:tokenURL:scope=desc,...", value)
+	}
+	name, flow, authURL, tokenURL := parts[0], parts[1], parts[2], parts[3]
+
+	var scheme *spec.SecurityScheme
+	switch flow {
+	case "implicit":
+		scheme = spec.OAuth2Implicit(authURL)
+	case "password":
+		scheme = spec.OAuth2Password(tokenURL)
+	case "application":
+		scheme = spec.OAuth2Application(tokenURL)
+	case "accessCode":
+		scheme = spec.OAuth2AccessToken(authURL, tokenURL)
+	default:
+		return "", nil, errs.Newf("invalid oauth2 flow %q in --sec-oauth2 %q; must be implicit, password, application, or accessCode", flow, value)
+	}
+	if len(parts) == 5 {
+		for _, pair := range strings.Split(parts[4], ",") {
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) == 2 {
+				scheme.AddScope(kv[0], kv[1])
+			}
+		}
+	}
+	return name, scheme, nil
+}
+
+func parseDefaultSecurity(value string) (string, []string) {
+	parts := strings.SplitN(value, ":", 2)
+	var scopes []string
+	if len(parts) == 2 && parts[1] != "" {
+		scopes = strings.Split(parts[1], ",")
+	}
+	return parts[0], scopes
+}
+
+// urlPattern matches a full scheme://host[:port][/path] URL, including any
+// port, so it can be carved out of a colon-delimited value as a single
+// atomic field.
+var urlPattern = regexp.MustCompile(`[A-Za-z][A-Za-z0-9+.-]*://[^/:\s]+(?::[0-9]+)?(?:/[^\s:]*)?`)
+
+// splitPreservingURLs splits value on ":" without breaking apart any
+// embedded URL, including its scheme separator and an explicit port, since
+// --sec-oauth2 values carry full URLs (e.g. authURL/tokenURL with a
+// :8443-style port) alongside colon-delimited fields.
+func splitPreservingURLs(value string) []string {
+	matches := urlPattern.FindAllStringIndex(value, -1)
+	var parts []string
+	pos := 0
+	for _, m := range matches {
+		parts = append(parts, splitColonFields(value[pos:m[0]])...)
+		parts = append(parts, value[m[0]:m[1]])
+		pos = m[1]
+	}
+	parts = append(parts, splitColonFields(value[pos:])...)
+	return parts
+}
+
+// splitColonFields splits a non-URL segment on ":", trimming the leading
+// and/or trailing colon left behind by the adjacent URL fields that
+// splitPreservingURLs carved out around it.
+func splitColonFields(s string) []string {
+	trimmed := strings.Trim(s, ":")
+	if trimmed == "" {
+		return nil
+	}
+	return strings.Split(trimmed, ":")
+}
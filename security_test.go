@@ -0,0 +1,101 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitPreservingURLs(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{
+			name:  "no urls",
+			value: "myBasic:a description",
+			want:  []string{"myBasic", "a description"},
+		},
+		{
+			name:  "urls without ports",
+			value: "myOAuth:implicit:https://auth.example.com/authorize",
+			want:  []string{"myOAuth", "implicit", "https://auth.example.com/authorize"},
+		},
+		{
+			name:  "ported urls and a trailing scope list",
+			value: "myOAuth:accessCode:https://auth.example.com:8443/authorize:https://auth.example.com:8443/token:read=Read access",
+			want: []string{
+				"myOAuth", "accessCode",
+				"https://auth.example.com:8443/authorize",
+				"https://auth.example.com:8443/token",
+				"read=Read access",
+			},
+		},
+		{
+			name:  "localhost url with port",
+			value: "myOAuth:password:http://localhost:8080/token",
+			want:  []string{"myOAuth", "password", "http://localhost:8080/token"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitPreservingURLs(tt.value)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitPreservingURLs(%q) = %#v, want %#v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSecOAuth2(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		wantName string
+		wantErr  bool
+	}{
+		{
+			name:     "accessCode flow with ported urls",
+			value:    "myOAuth:accessCode:https://auth.example.com:8443/authorize:https://auth.example.com:8443/token:read=Read access",
+			wantName: "myOAuth",
+		},
+		{
+			name:    "missing fields",
+			value:   "myOAuth:implicit",
+			wantErr: true,
+		},
+		{
+			name:    "unknown flow",
+			value:   "myOAuth:bogus:https://a:https://b",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, scheme, err := parseSecOAuth2(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got scheme %+v", scheme)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if name != tt.wantName {
+				t.Errorf("got name %q, want %q", name, tt.wantName)
+			}
+			if scheme.Flow != "accessCode" {
+				t.Errorf("got flow %q, want accessCode", scheme.Flow)
+			}
+			if scheme.AuthorizationURL != "https://auth.example.com:8443/authorize" {
+				t.Errorf("got authURL %q, want port preserved", scheme.AuthorizationURL)
+			}
+			if scheme.TokenURL != "https://auth.example.com:8443/token" {
+				t.Errorf("got tokenURL %q, want port preserved", scheme.TokenURL)
+			}
+		})
+	}
+}
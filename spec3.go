@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/go-openapi/spec"
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+// convertToOpenAPI3 rewrites a Swagger 2.0 document as an OpenAPI 3.x document.
+// It works on the generic JSON map rather than kin-openapi's typed model because
+// the source data already round-trips through spec.Swagger's own JSON tags, and
+// a structural map rewrite is far less code than reconstructing every field on
+// openapi3.T by hand.
+func convertToOpenAPI3(swagger *spec.Swagger, version string) (map[string]interface{}, error) {
+	raw, err := swagger.MarshalJSON()
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	var doc map[string]interface{}
+	if err = json.Unmarshal(raw, &doc); err != nil {
+		return nil, errs.Wrap(err)
+	}
+
+	openapi3Version := "3.0.3"
+	if version == "3.1" {
+		openapi3Version = "3.1.0"
+	}
+
+	out := map[string]interface{}{
+		"openapi": openapi3Version,
+		"info":    doc["info"],
+	}
+	out["servers"] = convertServers(doc)
+
+	components := map[string]interface{}{}
+	if defs, ok := doc["definitions"].(map[string]interface{}); ok {
+		components["schemas"] = rewriteRefs(defs)
+	}
+	if secDefs, ok := doc["securityDefinitions"].(map[string]interface{}); ok {
+		components["securitySchemes"] = convertSecurityDefinitions(secDefs)
+	}
+	if len(components) > 0 {
+		out["components"] = rewriteRefs(components)
+	}
+
+	if paths, ok := doc["paths"].(map[string]interface{}); ok {
+		out["paths"] = convertPaths(paths)
+	}
+	if sec, ok := doc["security"]; ok {
+		out["security"] = sec
+	}
+	if tags, ok := doc["tags"]; ok {
+		out["tags"] = tags
+	}
+
+	return rewriteRefs(out).(map[string]interface{}), nil
+}
+
+// convertServers builds the OpenAPI 3 `servers` array from Swagger 2.0's
+// `host` + `basePath` + `schemes`, defaulting to `https` when no scheme is set.
+func convertServers(doc map[string]interface{}) []map[string]interface{} {
+	host, _ := doc["host"].(string)
+	basePath, _ := doc["basePath"].(string)
+	schemes, _ := doc["schemes"].([]interface{})
+	if host == "" && basePath == "" {
+		return nil
+	}
+	if len(schemes) == 0 {
+		schemes = []interface{}{"https"}
+	}
+	servers := make([]map[string]interface{}, 0, len(schemes))
+	for _, s := range schemes {
+		scheme, _ := s.(string)
+		servers = append(servers, map[string]interface{}{
+			"url": scheme + "://" + host + basePath,
+		})
+	}
+	return servers
+}
+
+// convertSecurityDefinitions maps Swagger 2.0 security scheme objects onto
+// their OpenAPI 3 `components.securitySchemes` equivalents.
+func convertSecurityDefinitions(defs map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(defs))
+	for name, v := range defs {
+		def, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		scheme := map[string]interface{}{}
+		switch def["type"] {
+		case "basic":
+			scheme["type"] = "http"
+			scheme["scheme"] = "basic"
+		case "apiKey":
+			scheme["type"] = "apiKey"
+			scheme["name"] = def["name"]
+			scheme["in"] = def["in"]
+		case "oauth2":
+			scheme["type"] = "oauth2"
+			flow := map[string]interface{}{}
+			if scopes, ok := def["scopes"]; ok {
+				flow["scopes"] = scopes
+			}
+			if url, ok := def["authorizationUrl"]; ok {
+				flow["authorizationUrl"] = url
+			}
+			if url, ok := def["tokenUrl"]; ok {
+				flow["tokenUrl"] = url
+			}
+			flowName := "implicit"
+			switch def["flow"] {
+			case "password":
+				flowName = "password"
+			case "application":
+				flowName = "clientCredentials"
+			case "accessCode":
+				flowName = "authorizationCode"
+			}
+			scheme["flows"] = map[string]interface{}{flowName: flow}
+		default:
+			scheme = def
+		}
+		if desc, ok := def["description"]; ok {
+			scheme["description"] = desc
+		}
+		out[name] = scheme
+	}
+	return out
+}
+
+// convertPaths rewrites each operation's body parameter (if any) into a
+// `requestBody` with a `content` map, and each response's top-level `schema`
+// into `content.<mediaType>.schema`, leaving everything else untouched.
+func convertPaths(paths map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(paths))
+	for path, v := range paths {
+		pathItem, ok := v.(map[string]interface{})
+		if !ok {
+			out[path] = v
+			continue
+		}
+		converted := make(map[string]interface{}, len(pathItem))
+		for method, ov := range pathItem {
+			op, ok := ov.(map[string]interface{})
+			if !ok {
+				converted[method] = ov
+				continue
+			}
+			converted[method] = convertOperation(op)
+		}
+		out[path] = converted
+	}
+	return out
+}
+
+func convertOperation(op map[string]interface{}) map[string]interface{} {
+	if params, ok := op["parameters"].([]interface{}); ok {
+		var remaining []interface{}
+		for _, pv := range params {
+			param, ok := pv.(map[string]interface{})
+			if !ok {
+				remaining = append(remaining, pv)
+				continue
+			}
+			if param["in"] != "body" {
+				remaining = append(remaining, pv)
+				continue
+			}
+			content := map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": param["schema"],
+				},
+			}
+			requestBody := map[string]interface{}{"content": content}
+			if required, ok := param["required"].(bool); ok {
+				requestBody["required"] = required
+			}
+			if desc, ok := param["description"]; ok {
+				requestBody["description"] = desc
+			}
+			op["requestBody"] = requestBody
+		}
+		op["parameters"] = remaining
+	}
+
+	if responses, ok := op["responses"].(map[string]interface{}); ok {
+		for code, rv := range responses {
+			resp, ok := rv.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			schema, ok := resp["schema"]
+			if !ok {
+				continue
+			}
+			delete(resp, "schema")
+			resp["content"] = map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": schema,
+				},
+			}
+			responses[code] = resp
+		}
+	}
+
+	return op
+}
+
+// rewriteRefs recursively rewrites `#/definitions/...` JSON references to
+// `#/components/schemas/...` throughout a decoded JSON value.
+func rewriteRefs(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			if k == "$ref" {
+				if ref, ok := child.(string); ok {
+					val[k] = strings.Replace(ref, "#/definitions/", "#/components/schemas/", 1)
+					continue
+				}
+			}
+			val[k] = rewriteRefs(child)
+		}
+		return val
+	case []interface{}:
+		for i, child := range val {
+			val[i] = rewriteRefs(child)
+		}
+		return val
+	default:
+		return v
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-openapi/spec"
+)
+
+func TestConvertToOpenAPI3(t *testing.T) {
+	tests := []struct {
+		name    string
+		swagger string
+		version string
+		check   func(t *testing.T, doc map[string]interface{})
+	}{
+		{
+			name:    "body parameter becomes requestBody",
+			version: "3.0",
+			swagger: `{
+				"swagger": "2.0",
+				"info": {"title": "t", "version": "1"},
+				"paths": {
+					"/widgets": {
+						"post": {
+							"parameters": [{
+								"in": "body",
+								"name": "body",
+								"required": true,
+								"schema": {"type": "object"}
+							}]
+						}
+					}
+				}
+			}`,
+			check: func(t *testing.T, doc map[string]interface{}) {
+				op := mapAt(mapAt(mapAt(doc, "paths"), "/widgets"), "post")
+				if op["parameters"] != nil {
+					if params, ok := op["parameters"].([]interface{}); ok && len(params) != 0 {
+						t.Fatalf("expected body parameter to be removed, got %v", params)
+					}
+				}
+				reqBody := mapAt(op, "requestBody")
+				if reqBody == nil {
+					t.Fatal("expected requestBody to be set")
+				}
+				if required, _ := reqBody["required"].(bool); !required {
+					t.Fatal("expected requestBody.required to be true")
+				}
+				content := mapAt(reqBody, "content")
+				if mapAt(content, "application/json") == nil {
+					t.Fatal("expected requestBody.content.application/json to be set")
+				}
+			},
+		},
+		{
+			name:    "response schema moves into content",
+			version: "3.0",
+			swagger: `{
+				"swagger": "2.0",
+				"info": {"title": "t", "version": "1"},
+				"paths": {
+					"/widgets": {
+						"get": {
+							"responses": {
+								"200": {
+									"description": "ok",
+									"schema": {"type": "object"}
+								}
+							}
+						}
+					}
+				}
+			}`,
+			check: func(t *testing.T, doc map[string]interface{}) {
+				op := mapAt(mapAt(mapAt(doc, "paths"), "/widgets"), "get")
+				resp := mapAt(mapAt(op, "responses"), "200")
+				if _, ok := resp["schema"]; ok {
+					t.Fatal("expected top-level response schema to be removed")
+				}
+				schema := mapAt(mapAt(mapAt(resp, "content"), "application/json"), "schema")
+				if schema == nil {
+					t.Fatal("expected responses.200.content.application/json.schema to be set")
+				}
+			},
+		},
+		{
+			name:    "definitions refs rewritten to components/schemas",
+			version: "3.1",
+			swagger: `{
+				"swagger": "2.0",
+				"info": {"title": "t", "version": "1"},
+				"definitions": {
+					"Widget": {"type": "object"}
+				},
+				"paths": {
+					"/widgets": {
+						"get": {
+							"responses": {
+								"200": {
+									"description": "ok",
+									"schema": {"$ref": "#/definitions/Widget"}
+								}
+							}
+						}
+					}
+				}
+			}`,
+			check: func(t *testing.T, doc map[string]interface{}) {
+				if doc["openapi"] != "3.1.0" {
+					t.Fatalf("expected openapi 3.1.0, got %v", doc["openapi"])
+				}
+				op := mapAt(mapAt(mapAt(doc, "paths"), "/widgets"), "get")
+				resp := mapAt(mapAt(op, "responses"), "200")
+				schema := mapAt(mapAt(mapAt(resp, "content"), "application/json"), "schema")
+				if ref, _ := schema["$ref"].(string); ref != "#/components/schemas/Widget" {
+					t.Fatalf("expected rewritten $ref, got %v", schema["$ref"])
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var swagger spec.Swagger
+			if err := json.Unmarshal([]byte(tt.swagger), &swagger); err != nil {
+				t.Fatalf("unmarshal fixture: %v", err)
+			}
+			doc, err := convertToOpenAPI3(&swagger, tt.version)
+			if err != nil {
+				t.Fatalf("convertToOpenAPI3: %v", err)
+			}
+			tt.check(t, doc)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-openapi/loads"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/validate"
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+// validateSwagger2 validates a Swagger 2.0 document, returning a wrapped error
+// listing every diagnostic found so a broken spec fails the build loudly
+// instead of producing an unusable swagger.json.
+func validateSwagger2(jData []byte) error {
+	document, err := loads.Analyzed(json.RawMessage(jData), "2.0")
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	result := validate.NewSpecValidator(document.Schema(), strfmt.Default)
+	res, _ := result.Validate(document)
+	if res.HasErrors() {
+		return errs.New(formatValidationErrors(res.Errors))
+	}
+	return nil
+}
+
+// validateOpenAPI3 validates an OpenAPI 3.x document produced by
+// convertToOpenAPI3, returning kin-openapi's error for the first failing node
+// it finds.
+func validateOpenAPI3(doc map[string]interface{}) error {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	loader := openapi3.NewLoader()
+	spec3, err := loader.LoadFromData(raw)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	if err = spec3.Validate(context.Background()); err != nil {
+		return errs.Wrap(err)
+	}
+	return nil
+}
+
+func formatValidationErrors(errors []error) string {
+	msg := fmt.Sprintf("spec validation failed with %d error(s):", len(errors))
+	for _, e := range errors {
+		msg += "\n  - " + e.Error()
+	}
+	return msg
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/spec"
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+// splitSitesByTag emits one documentation site per distinct first tag under
+// <destDir>/<apiDir>/<tag>/, each carrying only the paths tagged with it and
+// the transitive closure of definitions those paths reference, plus a
+// top-level index.html linking to every sub-site.
+func splitSitesByTag(swagger *spec.Swagger, destDir, apiDir, baseName, title, serverURL, renderer string, embedded, generateHtml, rendererAssetsLocal bool, badgeMap map[string]string) error {
+	tagPaths := make(map[string]*spec.Paths)
+	for path, pathItem := range swagger.Paths.Paths {
+		for method, op := range operationsByMethod(pathItem) {
+			if op == nil || len(op.Tags) == 0 {
+				continue
+			}
+			tag := op.Tags[0]
+			tp, ok := tagPaths[tag]
+			if !ok {
+				tp = &spec.Paths{Paths: make(map[string]spec.PathItem)}
+				tagPaths[tag] = tp
+			}
+			entry, ok := tp.Paths[path]
+			if !ok {
+				entry.Parameters = pathItem.Parameters
+			}
+			setOperation(&entry, method, op)
+			tp.Paths[path] = entry
+		}
+	}
+
+	tags := make([]string, 0, len(tagPaths))
+	for tag := range tagPaths {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	apiRoot := filepath.Join(destDir, apiDir)
+	for _, tag := range tags {
+		tagDir := filepath.Join(apiRoot, sanitizeTag(tag))
+		if err := os.MkdirAll(tagDir, 0o755); err != nil {
+			return errs.Wrap(err)
+		}
+		tagSwagger := *swagger
+		tagSwagger.Paths = tagPaths[tag]
+		tagSwagger.Definitions = pruneDefinitions(swagger.Definitions, tagPaths[tag])
+		jData, err := json.MarshalIndent(&tagSwagger, "", "  ")
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		if err = writeDocSite(tagDir, baseName, title+" - "+tag, serverURL, renderer, embedded, generateHtml, rendererAssetsLocal, jData); err != nil {
+			return err
+		}
+	}
+
+	if !generateHtml {
+		return nil
+	}
+	return writeTagIndex(apiRoot, title, tags, badgeMap)
+}
+
+func operationsByMethod(pathItem spec.PathItem) map[string]*spec.Operation {
+	return map[string]*spec.Operation{
+		"get": pathItem.Get, "post": pathItem.Post, "put": pathItem.Put,
+		"delete": pathItem.Delete, "options": pathItem.Options,
+		"head": pathItem.Head, "patch": pathItem.Patch,
+	}
+}
+
+func setOperation(pathItem *spec.PathItem, method string, op *spec.Operation) {
+	switch method {
+	case "get":
+		pathItem.Get = op
+	case "post":
+		pathItem.Post = op
+	case "put":
+		pathItem.Put = op
+	case "delete":
+		pathItem.Delete = op
+	case "options":
+		pathItem.Options = op
+	case "head":
+		pathItem.Head = op
+	case "patch":
+		pathItem.Patch = op
+	}
+}
+
+var defRefPattern = regexp.MustCompile(`"#/definitions/([^"]+)"`)
+
+// pruneDefinitions walks paths' parameters and responses, collecting the
+// transitive closure of `#/definitions/...` refs they use.
+func pruneDefinitions(defs spec.Definitions, paths *spec.Paths) spec.Definitions {
+	raw, err := json.Marshal(paths)
+	if err != nil {
+		return spec.Definitions{}
+	}
+	queue := findDefRefs(raw)
+	seen := make(map[string]bool)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		schema, ok := defs[name]
+		if !ok {
+			continue
+		}
+		schemaRaw, err := json.Marshal(schema)
+		if err != nil {
+			continue
+		}
+		for _, ref := range findDefRefs(schemaRaw) {
+			if !seen[ref] {
+				queue = append(queue, ref)
+			}
+		}
+	}
+	pruned := make(spec.Definitions, len(seen))
+	for name := range seen {
+		if schema, ok := defs[name]; ok {
+			pruned[name] = schema
+		}
+	}
+	return pruned
+}
+
+func findDefRefs(raw []byte) []string {
+	matches := defRefPattern.FindAllStringSubmatch(string(raw), -1)
+	names := make([]string, 0, len(matches))
+	for _, m := range matches {
+		names = append(names, m[1])
+	}
+	return names
+}
+
+// sanitizeTag turns a tag into a safe directory name. It replaces path
+// separators and dots so a tag like ".." or "../../etc" can't escape
+// apiRoot when joined into tagDir.
+func sanitizeTag(tag string) string {
+	return strings.NewReplacer(" ", "-", "/", "-", `\`, "-", ".", "-").Replace(strings.ToLower(tag))
+}
+
+// writeTagIndex writes the top-level index.html linking to each per-tag site,
+// rendering each tag as a card colored from badgeMap when a color was set via --badges.
+func writeTagIndex(apiRoot, title string, tags []string, badgeMap map[string]string) error {
+	var cards strings.Builder
+	for _, tag := range tags {
+		color := badgeMap[tag]
+		if color == "" {
+			color = "#666"
+		}
+		cards.WriteString(fmt.Sprintf(`
+      <a class="tag-card" href="%s/index.html" style="border-color: %s">
+        <span class="tag-dot" style="background: %s"></span>%s
+      </a>`, sanitizeTag(tag), color, color, tag))
+	}
+	html := fmt.Sprintf(`<!doctype html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <title>%s</title>
+    <style>
+        body { font-family: sans-serif; background: #1e1e1e; color: #eee; padding: 2rem; }
+        .tag-grid { display: flex; flex-wrap: wrap; gap: 0.75rem; }
+        .tag-card { padding: 0.75rem 1.25rem; border: 2px solid #666; border-radius: 6px; color: #eee; text-decoration: none; }
+        .tag-dot { display: inline-block; width: 10px; height: 10px; border-radius: 50%%; margin-right: 0.5rem; }
+    </style>
+</head>
+<body>
+    <h1>%s</h1>
+    <div class="tag-grid">%s
+    </div>
+</body>
+</html>`, title, title, cards.String())
+	if err := os.WriteFile(filepath.Join(apiRoot, "index.html"), []byte(html), 0o644); err != nil {
+		return errs.Wrap(err)
+	}
+	return nil
+}
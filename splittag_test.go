@@ -0,0 +1,67 @@
+package main
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/go-openapi/spec"
+)
+
+func TestSanitizeTag(t *testing.T) {
+	tests := []struct {
+		name string
+		tag  string
+		want string
+	}{
+		{name: "lowercases and dashes spaces", tag: "User Accounts", want: "user-accounts"},
+		{name: "dashes slashes", tag: "a/b", want: "a-b"},
+		{name: "rejects dot segment", tag: "..", want: "--"},
+		{name: "rejects path escape", tag: "../../etc", want: "------etc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeTag(tt.tag); got != tt.want {
+				t.Errorf("sanitizeTag(%q) = %q, want %q", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPruneDefinitions(t *testing.T) {
+	defs := spec.Definitions{
+		"Widget": *spec.MapProperty(spec.RefProperty("#/definitions/Owner")),
+		"Owner":  *spec.StringProperty(),
+		"Unused": *spec.StringProperty(),
+	}
+	paths := &spec.Paths{Paths: map[string]spec.PathItem{
+		"/widgets": {PathItemProps: spec.PathItemProps{Get: &spec.Operation{
+			OperationProps: spec.OperationProps{
+				Responses: &spec.Responses{ResponsesProps: spec.ResponsesProps{
+					StatusCodeResponses: map[int]spec.Response{
+						200: {ResponseProps: spec.ResponseProps{
+							Schema: spec.RefProperty("#/definitions/Widget"),
+						}},
+					},
+				}},
+			},
+		}}},
+	}}
+
+	pruned := pruneDefinitions(defs, paths)
+
+	var names []string
+	for name := range pruned {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	want := []string{"Owner", "Widget"}
+	if len(names) != len(want) {
+		t.Fatalf("got definitions %v, want %v", names, want)
+	}
+	for i, n := range want {
+		if names[i] != n {
+			t.Errorf("got definitions %v, want %v", names, want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileCacheUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.go")
+	if err := os.WriteFile(path, []byte("package a"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	c := newFileCache()
+	if c.unchanged(path) {
+		t.Error("first call should report changed (nothing cached yet)")
+	}
+	if !c.unchanged(path) {
+		t.Error("second call with identical bytes should report unchanged")
+	}
+
+	if err := os.WriteFile(path, []byte("package a // edited"), 0o644); err != nil {
+		t.Fatalf("rewrite: %v", err)
+	}
+	if c.unchanged(path) {
+		t.Error("changed bytes should report changed")
+	}
+	if !c.unchanged(path) {
+		t.Error("re-checking the new bytes should report unchanged")
+	}
+}
+
+func TestFileCacheInvalidate(t *testing.T) {
+	dir := t.TempDir()
+	pkgDir := filepath.Join(dir, "pkg")
+	if err := os.MkdirAll(pkgDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	a := filepath.Join(pkgDir, "a.go")
+	b := filepath.Join(pkgDir, "b.go")
+	for _, p := range []string{a, b} {
+		if err := os.WriteFile(p, []byte("package pkg"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", p, err)
+		}
+	}
+
+	c := newFileCache()
+	c.unchanged(a)
+	c.unchanged(b)
+
+	c.invalidate(a)
+
+	if c.unchanged(a) {
+		t.Error("invalidate should drop a.go's cached hash")
+	}
+	if c.unchanged(b) {
+		t.Error("invalidate should also drop sibling b.go in the same package directory")
+	}
+}